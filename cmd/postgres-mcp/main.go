@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -33,6 +34,9 @@ type config struct {
 	RequestTimeout time.Duration
 	HTTPStateless  bool
 	HTTPJSON       bool
+	Pool           postgresmcp.PoolOptions
+	WaitForDB      time.Duration
+	Metrics        bool
 }
 
 func main() {
@@ -45,18 +49,27 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	pool, err := configurePool(ctx, cfg.DatabaseURL, logger)
+	pool, err := postgresmcp.WaitForPool(ctx, cfg.DatabaseURL, postgresmcp.WaitOptions{
+		Pool:     cfg.Pool,
+		Deadline: cfg.WaitForDB,
+		Logger:   logger,
+	})
 	if err != nil {
 		log.Fatalf("database error: %v", err)
 	}
+	logPoolSettings(pool, logger)
 	defer pool.Close()
 
+	metrics := postgresmcp.NewMetrics(pool)
+	metrics.PublishExpvar("postgres_mcp")
+
 	serverOpts := postgresmcp.ServerOptions{
-		Pool:           pool,
-		ReadOnly:       cfg.ReadOnly,
-		MaxRows:        cfg.MaxRows,
-		RequestTimeout: cfg.RequestTimeout,
-		Logger:         logger,
+		Pool:            pool,
+		ReadOnly:        cfg.ReadOnly,
+		MaxRows:         cfg.MaxRows,
+		RequestTimeout:  cfg.RequestTimeout,
+		Logger:          logger,
+		Instrumentation: metrics,
 	}
 
 	switch cfg.Mode {
@@ -70,7 +83,7 @@ func main() {
 			log.Fatalf("stdio session ended with error: %v", err)
 		}
 	case modeHTTP:
-		if err := runHTTP(ctx, cfg, serverOpts, logger); err != nil {
+		if err := runHTTP(ctx, cfg, serverOpts, metrics, logger); err != nil {
 			log.Fatalf("http server error: %v", err)
 		}
 	default:
@@ -89,6 +102,15 @@ func parseConfig() (config, error) {
 	timeout := flag.Duration("timeout", 0, "Per-request timeout (e.g. 30s). 0 disables")
 	httpStateless := flag.Bool("http-stateless", false, "Serve streamable HTTP sessions without retaining state")
 	httpJSON := flag.Bool("http-json", false, "Prefer JSON responses for single-message HTTP POSTs")
+	poolMaxConns := flag.Int("pool-max-conns", envInt("PGPOOL_MAX_CONNS", 0), "Maximum pgxpool connections (0 uses pgx default)")
+	poolMinConns := flag.Int("pool-min-conns", envInt("PGPOOL_MIN_CONNS", 0), "Minimum idle pgxpool connections (0 uses pgx default)")
+	poolMaxConnLifetime := flag.Duration("pool-max-conn-lifetime", envDuration("PGPOOL_MAX_CONN_LIFETIME", 0), "Maximum lifetime of a pooled connection (0 uses pgx default)")
+	poolMaxConnIdleTime := flag.Duration("pool-max-conn-idle-time", envDuration("PGPOOL_MAX_CONN_IDLE_TIME", 0), "Maximum idle time of a pooled connection (0 uses pgx default)")
+	poolHealthCheckPeriod := flag.Duration("pool-health-check-period", envDuration("PGPOOL_HEALTH_CHECK_PERIOD", 0), "How often the pool checks idle connections (0 uses pgx default)")
+	statementCacheCapacity := flag.Int("statement-cache-capacity", envInt("PGSTATEMENT_CACHE_CAPACITY", 0), "Prepared statement cache capacity per connection (0 uses pgx default)")
+	descriptionCacheCapacity := flag.Int("description-cache-capacity", envInt("PGDESCRIPTION_CACHE_CAPACITY", 0), "Statement description cache capacity per connection (0 uses pgx default)")
+	waitForDB := flag.Duration("wait-for-db", 5*time.Second, "How long to retry connecting to the database before giving up, backing off exponentially between attempts")
+	metrics := flag.Bool("metrics", false, "Expose a Prometheus /metrics endpoint (http mode only)")
 
 	flag.Parse()
 
@@ -110,6 +132,21 @@ func parseConfig() (config, error) {
 	cfg.RequestTimeout = *timeout
 	cfg.HTTPStateless = *httpStateless
 	cfg.HTTPJSON = *httpJSON
+	cfg.WaitForDB = *waitForDB
+	cfg.Metrics = *metrics
+
+	cfg.Pool = postgresmcp.PoolOptions{
+		MaxConns:                 int32(*poolMaxConns),
+		MinConns:                 int32(*poolMinConns),
+		MaxConnLifetime:          *poolMaxConnLifetime,
+		MaxConnIdleTime:          *poolMaxConnIdleTime,
+		HealthCheckPeriod:        *poolHealthCheckPeriod,
+		StatementCacheCapacity:   *statementCacheCapacity,
+		DescriptionCacheCapacity: *descriptionCacheCapacity,
+	}
+	if cfg.Pool.MinConns > 0 && cfg.Pool.MaxConns > 0 && cfg.Pool.MinConns > cfg.Pool.MaxConns {
+		return cfg, fmt.Errorf("pool-min-conns (%d) exceeds pool-max-conns (%d)", cfg.Pool.MinConns, cfg.Pool.MaxConns)
+	}
 
 	return cfg, nil
 }
@@ -121,38 +158,40 @@ func defaultDatabaseURL() string {
 	return ""
 }
 
-func configurePool(ctx context.Context, dsn string, logger *log.Logger) (*pgxpool.Pool, error) {
-	cfg, err := pgxpool.ParseConfig(dsn)
-	if err != nil {
-		return nil, fmt.Errorf("parse config: %w", err)
+func envInt(name string, fallback int) int {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback
 	}
-	if cfg.ConnConfig.RuntimeParams == nil {
-		cfg.ConnConfig.RuntimeParams = make(map[string]string)
-	}
-	if _, exists := cfg.ConnConfig.RuntimeParams["application_name"]; !exists {
-		cfg.ConnConfig.RuntimeParams["application_name"] = "postgres-mcp-go"
-	}
-
-	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	n, err := strconv.Atoi(strings.TrimSpace(v))
 	if err != nil {
-		return nil, fmt.Errorf("creating pool: %w", err)
+		return fallback
 	}
+	return n
+}
 
-	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-	if err := pool.Ping(pingCtx); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("connectivity check failed: %w", err)
+func envDuration(name string, fallback time.Duration) time.Duration {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback
 	}
-
-	if logger != nil {
-		logger.Printf("connected to postgres host=%s database=%s", cfg.ConnConfig.Host, cfg.ConnConfig.Database)
+	d, err := time.ParseDuration(strings.TrimSpace(v))
+	if err != nil {
+		return fallback
 	}
+	return d
+}
 
-	return pool, nil
+func logPoolSettings(pool *pgxpool.Pool, logger *log.Logger) {
+	if logger == nil {
+		return
+	}
+	cfg := pool.Config()
+	logger.Printf("connected to postgres host=%s database=%s poolMaxConns=%d poolMinConns=%d poolMaxConnLifetime=%s poolMaxConnIdleTime=%s poolHealthCheckPeriod=%s statementCacheCapacity=%d descriptionCacheCapacity=%d",
+		cfg.ConnConfig.Host, cfg.ConnConfig.Database, cfg.MaxConns, cfg.MinConns, cfg.MaxConnLifetime, cfg.MaxConnIdleTime, cfg.HealthCheckPeriod, cfg.ConnConfig.StatementCacheCapacity, cfg.ConnConfig.DescriptionCacheCapacity)
 }
 
-func runHTTP(ctx context.Context, cfg config, serverOpts postgresmcp.ServerOptions, logger *log.Logger) error {
+func runHTTP(ctx context.Context, cfg config, serverOpts postgresmcp.ServerOptions, metrics *postgresmcp.Metrics, logger *log.Logger) error {
 	getServer := func(*http.Request) *mcp.Server {
 		srv, err := postgresmcp.NewServer(serverOpts)
 		if err != nil {
@@ -169,15 +208,21 @@ func runHTTP(ctx context.Context, cfg config, serverOpts postgresmcp.ServerOptio
 		JSONResponse: cfg.HTTPJSON,
 	})
 
+	mux := http.NewServeMux()
+	mux.Handle("/", streamableHandler)
+	if cfg.Metrics {
+		mux.Handle("/metrics", metrics.Handler())
+	}
+
 	srv := &http.Server{
 		Addr:              cfg.ListenAddr,
-		Handler:           httpLoggingMiddleware(streamableHandler, logger),
+		Handler:           httpLoggingMiddleware(mux, logger),
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
 	errCh := make(chan error, 1)
 	go func() {
-		logger.Printf("streamable HTTP listening on %s stateless=%t jsonResponse=%t", cfg.ListenAddr, cfg.HTTPStateless, cfg.HTTPJSON)
+		logger.Printf("streamable HTTP listening on %s stateless=%t jsonResponse=%t metrics=%t", cfg.ListenAddr, cfg.HTTPStateless, cfg.HTTPJSON, cfg.Metrics)
 		errCh <- srv.ListenAndServe()
 	}()
 
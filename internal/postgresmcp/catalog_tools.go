@@ -0,0 +1,428 @@
+package postgresmcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// catalogHandler backs the read-only schema introspection tools
+// (postgres.list_schemas, postgres.list_tables, postgres.describe_table,
+// postgres.list_functions) plus postgres.explain. It reuses the same pool,
+// timeout, and row-limit machinery as queryHandler. The catalog statements
+// are read-only by construction; postgres.explain only plans (never runs)
+// the caller's statement, and when ReadOnly is set it also rejects mutating
+// statements, the same way postgres.query does.
+type catalogHandler struct {
+	pool            querier
+	readOnly        bool
+	maxRows         int
+	requestTimeout  time.Duration
+	instrumentation Instrumentation
+}
+
+func registerCatalogTools(server *mcp.Server, handler *catalogHandler) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "postgres.list_schemas",
+		Description: "List schemas in the connected database.",
+	}, handler.listSchemas)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "postgres.list_tables",
+		Description: "List tables, optionally filtered by schema.",
+	}, handler.listTables)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "postgres.describe_table",
+		Description: "Describe a table's columns, primary key, foreign keys, and indexes.",
+	}, handler.describeTable)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "postgres.list_functions",
+		Description: "List functions, optionally filtered by schema.",
+	}, handler.listFunctions)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "postgres.explain",
+		Description: "Explain a SQL statement's plan without executing it.",
+	}, handler.explain)
+}
+
+// run executes a parameterized catalog query and reports it to
+// instrumentation the same way queryHandler.call does.
+func (h *catalogHandler) run(ctx context.Context, tool, sqlText string, params []any) (*mcp.CallToolResult, queryOutput, error) {
+	rec := h.instrumentation.StartCall(tool)
+	ctx, cancel := applyTimeout(ctx, h.requestTimeout)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	start := time.Now()
+	out, err := execQuery(ctx, h.pool, sqlText, params, h.maxRows)
+	rec.Rows(out.RowCount, out.Truncated)
+	rec.End(err)
+	if err != nil {
+		return nil, queryOutput{}, err
+	}
+	out.Elapsed = time.Since(start).Round(time.Millisecond).String()
+	return nil, out, nil
+}
+
+type listSchemasInput struct{}
+
+func (h *catalogHandler) listSchemas(ctx context.Context, _ *mcp.CallToolRequest, _ listSchemasInput) (*mcp.CallToolResult, queryOutput, error) {
+	const q = `SELECT schema_name FROM information_schema.schemata ORDER BY schema_name`
+	return h.run(ctx, "postgres.list_schemas", q, nil)
+}
+
+type listTablesInput struct {
+	Schema string `json:"schema,omitempty" jsonschema:"title=Schema filter,description=Restrict results to this schema"`
+}
+
+func (h *catalogHandler) listTables(ctx context.Context, _ *mcp.CallToolRequest, input listTablesInput) (*mcp.CallToolResult, queryOutput, error) {
+	schema := strings.TrimSpace(input.Schema)
+	q := `SELECT table_schema, table_name, table_type FROM information_schema.tables
+	      WHERE table_schema NOT IN ('pg_catalog', 'information_schema')`
+	var params []any
+	if schema != "" {
+		q += ` AND table_schema = $1`
+		params = append(params, schema)
+	}
+	q += ` ORDER BY table_schema, table_name`
+	return h.run(ctx, "postgres.list_tables", q, params)
+}
+
+type listFunctionsInput struct {
+	Schema string `json:"schema,omitempty" jsonschema:"title=Schema filter,description=Restrict results to this schema"`
+}
+
+func (h *catalogHandler) listFunctions(ctx context.Context, _ *mcp.CallToolRequest, input listFunctionsInput) (*mcp.CallToolResult, queryOutput, error) {
+	schema := strings.TrimSpace(input.Schema)
+	q := `SELECT n.nspname AS schema, p.proname AS name,
+	             pg_get_function_arguments(p.oid) AS arguments,
+	             pg_get_function_result(p.oid) AS returns
+	      FROM pg_catalog.pg_proc p
+	      JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+	      WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')`
+	var params []any
+	if schema != "" {
+		q += ` AND n.nspname = $1`
+		params = append(params, schema)
+	}
+	q += ` ORDER BY n.nspname, p.proname`
+	return h.run(ctx, "postgres.list_functions", q, params)
+}
+
+type describeTableInput struct {
+	Schema string `json:"schema,omitempty" jsonschema:"title=Schema,description=Schema containing the table; defaults to the first match on search_path"`
+	Table  string `json:"table" jsonschema:"title=Table name"`
+}
+
+type columnInfo struct {
+	Name     string  `json:"name"`
+	DataType string  `json:"dataType"`
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default,omitempty"`
+}
+
+type foreignKeyInfo struct {
+	ConstraintName   string `json:"constraintName"`
+	Column           string `json:"column"`
+	ReferencedTable  string `json:"referencedTable"`
+	ReferencedColumn string `json:"referencedColumn"`
+}
+
+type indexInfo struct {
+	Name      string   `json:"name"`
+	Columns   []string `json:"columns"`
+	IsUnique  bool     `json:"isUnique"`
+	IsPrimary bool     `json:"isPrimary"`
+}
+
+type describeTableOutput struct {
+	Schema      string           `json:"schema"`
+	Table       string           `json:"table"`
+	Columns     []columnInfo     `json:"columns"`
+	PrimaryKey  []string         `json:"primaryKey,omitempty"`
+	ForeignKeys []foreignKeyInfo `json:"foreignKeys,omitempty"`
+	Indexes     []indexInfo      `json:"indexes,omitempty"`
+	Elapsed     string           `json:"elapsed"`
+}
+
+func (h *catalogHandler) describeTable(ctx context.Context, _ *mcp.CallToolRequest, input describeTableInput) (*mcp.CallToolResult, describeTableOutput, error) {
+	table := strings.TrimSpace(input.Table)
+	if table == "" {
+		return nil, describeTableOutput{}, newValidationError("table must not be empty")
+	}
+	schema := strings.TrimSpace(input.Schema)
+
+	rec := h.instrumentation.StartCall("postgres.describe_table")
+	ctx, cancel := applyTimeout(ctx, h.requestTimeout)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	start := time.Now()
+	out, err := h.doDescribeTable(ctx, schema, table)
+	rec.Rows(int64(len(out.Columns)), false)
+	rec.End(err)
+	if err != nil {
+		return nil, describeTableOutput{}, err
+	}
+	out.Elapsed = time.Since(start).Round(time.Millisecond).String()
+	return nil, out, nil
+}
+
+func (h *catalogHandler) doDescribeTable(ctx context.Context, schema, table string) (describeTableOutput, error) {
+	ident := table
+	if schema != "" {
+		ident = schema + "." + table
+	}
+
+	var oid uint32
+	var resolvedSchema, resolvedTable string
+	if err := h.queryRow(ctx, `
+		SELECT c.oid, n.nspname, c.relname
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.oid = to_regclass($1)::oid
+	`, []any{ident}, &oid, &resolvedSchema, &resolvedTable); err != nil {
+		return describeTableOutput{}, fmt.Errorf("postgresmcp: table %q not found: %w", ident, err)
+	}
+
+	out := describeTableOutput{Schema: resolvedSchema, Table: resolvedTable}
+
+	columns, err := h.describeColumns(ctx, oid)
+	if err != nil {
+		return describeTableOutput{}, err
+	}
+	out.Columns = columns
+
+	pk, err := h.describePrimaryKey(ctx, oid)
+	if err != nil {
+		return describeTableOutput{}, err
+	}
+	out.PrimaryKey = pk
+
+	fks, err := h.describeForeignKeys(ctx, oid)
+	if err != nil {
+		return describeTableOutput{}, err
+	}
+	out.ForeignKeys = fks
+
+	indexes, err := h.describeIndexes(ctx, oid)
+	if err != nil {
+		return describeTableOutput{}, err
+	}
+	out.Indexes = indexes
+
+	return out, nil
+}
+
+func (h *catalogHandler) describeColumns(ctx context.Context, oid uint32) ([]columnInfo, error) {
+	rows, err := h.pool.Query(ctx, `
+		SELECT a.attname,
+		       pg_catalog.format_type(a.atttypid, a.atttypmod),
+		       NOT a.attnotnull,
+		       pg_get_expr(d.adbin, d.adrelid)
+		FROM pg_catalog.pg_attribute a
+		LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`, oid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []columnInfo
+	for rows.Next() {
+		var col columnInfo
+		if err := rows.Scan(&col.Name, &col.DataType, &col.Nullable, &col.Default); err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+func (h *catalogHandler) describePrimaryKey(ctx context.Context, oid uint32) ([]string, error) {
+	rows, err := h.pool.Query(ctx, `
+		SELECT a.attname
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = ANY(con.conkey)
+		WHERE con.conrelid = $1 AND con.contype = 'p'
+		ORDER BY array_position(con.conkey, a.attnum)
+	`, oid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols = append(cols, name)
+	}
+	return cols, rows.Err()
+}
+
+// describeForeignKeys reports single-column foreign keys. Composite foreign
+// keys are not expanded; only the first referencing/referenced column pair
+// is reported.
+func (h *catalogHandler) describeForeignKeys(ctx context.Context, oid uint32) ([]foreignKeyInfo, error) {
+	rows, err := h.pool.Query(ctx, `
+		SELECT con.conname,
+		       a.attname,
+		       fn.nspname || '.' || fc.relname,
+		       fa.attname
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class fc ON fc.oid = con.confrelid
+		JOIN pg_catalog.pg_namespace fn ON fn.oid = fc.relnamespace
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = con.conkey[1]
+		JOIN pg_catalog.pg_attribute fa ON fa.attrelid = con.confrelid AND fa.attnum = con.confkey[1]
+		WHERE con.conrelid = $1 AND con.contype = 'f'
+		ORDER BY con.conname
+	`, oid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fks []foreignKeyInfo
+	for rows.Next() {
+		var fk foreignKeyInfo
+		if err := rows.Scan(&fk.ConstraintName, &fk.Column, &fk.ReferencedTable, &fk.ReferencedColumn); err != nil {
+			return nil, err
+		}
+		fks = append(fks, fk)
+	}
+	return fks, rows.Err()
+}
+
+func (h *catalogHandler) describeIndexes(ctx context.Context, oid uint32) ([]indexInfo, error) {
+	rows, err := h.pool.Query(ctx, `
+		SELECT ic.relname, ix.indisunique, ix.indisprimary,
+		       array(
+		           SELECT a.attname
+		           FROM pg_catalog.pg_attribute a
+		           WHERE a.attrelid = ix.indrelid AND a.attnum = ANY(ix.indkey)
+		           ORDER BY array_position(ix.indkey, a.attnum)
+		       )
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class ic ON ic.oid = ix.indexrelid
+		WHERE ix.indrelid = $1
+		ORDER BY ic.relname
+	`, oid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []indexInfo
+	for rows.Next() {
+		var idx indexInfo
+		if err := rows.Scan(&idx.Name, &idx.IsUnique, &idx.IsPrimary, &idx.Columns); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, rows.Err()
+}
+
+// queryRow runs sqlText and scans its single expected row into dest,
+// returning pgx.ErrNoRows if the query produced none.
+func (h *catalogHandler) queryRow(ctx context.Context, sqlText string, args []any, dest ...any) error {
+	rows, err := h.pool.Query(ctx, sqlText, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+type explainInput struct {
+	SQL  string `json:"sql" jsonschema:"title=SQL statement,description=Statement to explain; it is planned but not executed"`
+	Args []any  `json:"args,omitempty" jsonschema:"title=Parameters,description=Positional parameters that map to $1, $2, ..."`
+}
+
+type explainOutput struct {
+	Plan    any    `json:"plan"`
+	Elapsed string `json:"elapsed"`
+}
+
+func (h *catalogHandler) explain(ctx context.Context, _ *mcp.CallToolRequest, input explainInput) (*mcp.CallToolResult, explainOutput, error) {
+	sqlText := strings.TrimSpace(input.SQL)
+	if sqlText == "" {
+		return nil, explainOutput{}, newValidationError("sql must not be empty")
+	}
+	if !isSingleStatement(sqlText) {
+		return nil, explainOutput{}, newValidationError("only a single SQL statement is supported per call")
+	}
+	if h.readOnly && !isReadOnlyStatement(sqlText) {
+		return nil, explainOutput{}, newValidationError("mutating statements are disabled in read-only mode")
+	}
+
+	params := make([]any, len(input.Args))
+	for i := range input.Args {
+		params[i] = normalizeArgument(input.Args[i])
+	}
+
+	rec := h.instrumentation.StartCall("postgres.explain")
+	ctx, cancel := applyTimeout(ctx, h.requestTimeout)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	start := time.Now()
+	plan, err := h.runExplain(ctx, sqlText, params)
+	rec.End(err)
+	if err != nil {
+		return nil, explainOutput{}, err
+	}
+	return nil, explainOutput{Plan: plan, Elapsed: time.Since(start).Round(time.Millisecond).String()}, nil
+}
+
+func (h *catalogHandler) runExplain(ctx context.Context, sqlText string, params []any) (any, error) {
+	wrapped := "EXPLAIN (FORMAT JSON, ANALYZE false) " + sqlText
+	rows, err := h.pool.Query(ctx, wrapped, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("postgresmcp: EXPLAIN returned no rows")
+	}
+	var raw string
+	if err := rows.Scan(&raw); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var plan any
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		return nil, fmt.Errorf("postgresmcp: parse explain output: %w", err)
+	}
+	return plan, nil
+}
@@ -0,0 +1,400 @@
+package postgresmcp
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultCopyMaxBytes caps the size of a copy_to result and a copy_from
+// payload so a runaway export/import can't exhaust server memory.
+const defaultCopyMaxBytes = 10 << 20 // 10 MiB
+
+// copyHandler backs postgres.copy_to and postgres.copy_from, the
+// high-throughput COPY-based bulk export/import tools. It needs a dedicated
+// connection (via pool.Acquire) the same way listenHandler does, so unlike
+// queryHandler and catalogHandler it holds the concrete pool rather than the
+// querier interface.
+type copyHandler struct {
+	pool            *pgxpool.Pool
+	readOnly        bool
+	maxRows         int
+	maxBytes        int64
+	requestTimeout  time.Duration
+	instrumentation Instrumentation
+}
+
+func registerCopyTools(server *mcp.Server, handler *copyHandler) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "postgres.copy_to",
+		Description: "Bulk-export a query's results via server-side COPY TO STDOUT (CSV or line-delimited JSON).",
+	}, handler.copyTo)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "postgres.copy_from",
+		Description: "Bulk-import CSV rows into a table via server-side COPY FROM STDIN.",
+	}, handler.copyFrom)
+}
+
+type copyToInput struct {
+	Query   string `json:"query" jsonschema:"title=Query,description=SELECT statement whose results are copied out"`
+	Format  string `json:"format,omitempty" jsonschema:"title=Format,description=csv (default) or jsonl"`
+	MaxRows int    `json:"maxRows,omitempty" jsonschema:"title=Row limit,description=Override the default row limit for this call,minimum=1"`
+}
+
+func (h *copyHandler) copyTo(ctx context.Context, _ *mcp.CallToolRequest, input copyToInput) (*mcp.CallToolResult, queryOutput, error) {
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return nil, queryOutput{}, newValidationError("query must not be empty")
+	}
+	if !isSingleStatement(query) {
+		return nil, queryOutput{}, newValidationError("only a single SQL statement is supported per call")
+	}
+	if !isReadOnlyStatement(query) {
+		return nil, queryOutput{}, newValidationError("copy_to only supports read-only queries")
+	}
+
+	format := strings.ToLower(strings.TrimSpace(input.Format))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "jsonl" {
+		return nil, queryOutput{}, newValidationError("format must be csv or jsonl")
+	}
+
+	if input.MaxRows < 0 {
+		return nil, queryOutput{}, newValidationError("maxRows must be positive")
+	}
+	limit := h.maxRows
+	if input.MaxRows > 0 && (limit == 0 || input.MaxRows < limit) {
+		limit = input.MaxRows
+	}
+
+	rec := h.instrumentation.StartCall("postgres.copy_to")
+	ctx, cancel := applyTimeout(ctx, h.requestTimeout)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	start := time.Now()
+	var out queryOutput
+	var err error
+	if format == "csv" {
+		out, err = h.copyToCSV(ctx, query, limit)
+	} else {
+		out, err = h.copyToJSONL(ctx, query, limit)
+	}
+	rec.Rows(out.RowCount, out.Truncated)
+	rec.End(err)
+	if err != nil {
+		return nil, queryOutput{}, err
+	}
+	out.Elapsed = time.Since(start).Round(time.Millisecond).String()
+	return nil, out, nil
+}
+
+// copyToCSV runs query through the server-side COPY protocol via pgconn's
+// CopyTo, which is far cheaper than buffering rows through the regular
+// simple-query path for large result sets. limit is enforced by wrapping the
+// query and requesting one extra row, so an overflow can be detected and
+// trimmed without a second round trip.
+func (h *copyHandler) copyToCSV(ctx context.Context, query string, limit int) (queryOutput, error) {
+	conn, err := h.pool.Acquire(ctx)
+	if err != nil {
+		return queryOutput{}, fmt.Errorf("postgresmcp: acquire copy connection: %w", err)
+	}
+	defer conn.Release()
+
+	copySource := trimTrailingSemicolon(query)
+	if limit > 0 {
+		copySource = fmt.Sprintf("SELECT * FROM (%s) AS copy_to_source LIMIT %d", copySource, limit+1)
+	}
+
+	buf := newCappedBuffer(h.maxBytes)
+	if _, err := conn.Conn().PgConn().CopyTo(ctx, buf, fmt.Sprintf("COPY (%s) TO STDOUT WITH (FORMAT csv)", copySource)); err != nil {
+		return queryOutput{}, err
+	}
+
+	data, rowCount, truncated, err := trimCSVRows(buf.String(), limit)
+	if err != nil {
+		return queryOutput{}, fmt.Errorf("postgresmcp: parse copy_to csv output: %w", err)
+	}
+
+	return queryOutput{
+		Command:   "COPY",
+		RowCount:  int64(rowCount),
+		Truncated: truncated,
+		Data:      data,
+	}, nil
+}
+
+// copyToJSONL runs query through the regular query path and renders each row
+// as a line of JSON, since COPY has no native JSON output format.
+func (h *copyHandler) copyToJSONL(ctx context.Context, query string, limit int) (queryOutput, error) {
+	out, err := execQuery(ctx, h.pool, query, nil, limit)
+	if err != nil {
+		return queryOutput{}, err
+	}
+
+	var sb strings.Builder
+	for _, row := range out.Rows {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return queryOutput{}, err
+		}
+		if h.maxBytes > 0 && int64(sb.Len()+len(line)+1) > h.maxBytes {
+			return queryOutput{}, fmt.Errorf("postgresmcp: copy_to result exceeds %d byte limit", h.maxBytes)
+		}
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+
+	out.Data = sb.String()
+	out.Rows = nil
+	out.Columns = nil
+	return out, nil
+}
+
+// trimCSVRows counts the records in body and, when limit > 0, rewrites it to
+// contain at most limit records. It parses with encoding/csv rather than
+// splitting on "\n" so a record with a quoted, embedded newline (COPY quotes
+// any field containing one) is counted and trimmed as a single row instead
+// of being split across multiple "lines".
+func trimCSVRows(body string, limit int) (data string, rowCount int, truncated bool, err error) {
+	if body == "" {
+		return "", 0, false, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	if limit <= 0 {
+		records, err := reader.ReadAll()
+		if err != nil {
+			return "", 0, false, err
+		}
+		return body, len(records), false, nil
+	}
+
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+	count := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", 0, false, err
+		}
+		if count >= limit {
+			truncated = true
+			break
+		}
+		if err := writer.Write(record); err != nil {
+			return "", 0, false, err
+		}
+		count++
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", 0, false, err
+	}
+	return sb.String(), count, truncated, nil
+}
+
+// cappedBuffer is an io.Writer that refuses to grow past max bytes, so a
+// copy_to export can't be used to exhaust server memory. max <= 0 disables
+// the cap.
+type cappedBuffer struct {
+	buf strings.Builder
+	max int64
+}
+
+func newCappedBuffer(max int64) *cappedBuffer { return &cappedBuffer{max: max} }
+
+func (w *cappedBuffer) Write(p []byte) (int, error) {
+	if w.max > 0 && int64(w.buf.Len()+len(p)) > w.max {
+		return 0, fmt.Errorf("postgresmcp: copy_to result exceeds %d byte limit", w.max)
+	}
+	return w.buf.Write(p)
+}
+
+func (w *cappedBuffer) String() string { return w.buf.String() }
+
+type copyFromInput struct {
+	Table   string   `json:"table" jsonschema:"title=Table,description=Destination table (optionally schema-qualified)"`
+	Columns []string `json:"columns,omitempty" jsonschema:"title=Columns,description=Column names to load, in CSV order; defaults to the table's column order"`
+	CSV     string   `json:"csv" jsonschema:"title=CSV rows,description=CSV-encoded rows to load (no header row)"`
+}
+
+func (h *copyHandler) copyFrom(ctx context.Context, _ *mcp.CallToolRequest, input copyFromInput) (*mcp.CallToolResult, queryOutput, error) {
+	if h.readOnly {
+		return nil, queryOutput{}, newValidationError("copy_from is disabled in read-only mode")
+	}
+
+	table := strings.TrimSpace(input.Table)
+	if table == "" {
+		return nil, queryOutput{}, newValidationError("table must not be empty")
+	}
+	csvBody := input.CSV
+	if strings.TrimSpace(csvBody) == "" {
+		return nil, queryOutput{}, newValidationError("csv must not be empty")
+	}
+	if h.maxBytes > 0 && int64(len(csvBody)) > h.maxBytes {
+		return nil, queryOutput{}, newValidationError(fmt.Sprintf("csv exceeds %d byte limit", h.maxBytes))
+	}
+
+	rec := h.instrumentation.StartCall("postgres.copy_from")
+	ctx, cancel := applyTimeout(ctx, h.requestTimeout)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	start := time.Now()
+	out, err := h.doCopyFrom(ctx, table, input.Columns, csvBody)
+	rec.Rows(out.RowCount, false)
+	rec.End(err)
+	if err != nil {
+		return nil, queryOutput{}, err
+	}
+	out.Elapsed = time.Since(start).Round(time.Millisecond).String()
+	return nil, out, nil
+}
+
+type tableRef struct {
+	oid    uint32
+	schema string
+	table  string
+}
+
+func (h *copyHandler) doCopyFrom(ctx context.Context, table string, columns []string, csvBody string) (queryOutput, error) {
+	ref, err := h.resolveTable(ctx, table)
+	if err != nil {
+		return queryOutput{}, err
+	}
+
+	tableColumns, err := h.tableColumns(ctx, ref.oid)
+	if err != nil {
+		return queryOutput{}, err
+	}
+
+	useColumns := columns
+	if len(useColumns) == 0 {
+		useColumns = tableColumns
+	} else {
+		allowed := make(map[string]struct{}, len(tableColumns))
+		for _, c := range tableColumns {
+			allowed[c] = struct{}{}
+		}
+		for _, c := range useColumns {
+			if _, ok := allowed[c]; !ok {
+				return queryOutput{}, newValidationError(fmt.Sprintf("unknown column %q for table %s.%s", c, ref.schema, ref.table))
+			}
+		}
+	}
+
+	// CopyFrom's binary protocol encodes each value according to its Go
+	// type, so a plain string works for text-like columns but is not a
+	// general-purpose CSV-to-Postgres-type conversion; loading into
+	// non-text columns may require the caller to pre-cast via an
+	// intermediate staging table.
+	records, err := parseCSVRows(csvBody, len(useColumns))
+	if err != nil {
+		return queryOutput{}, err
+	}
+	if h.maxRows > 0 && len(records) > h.maxRows {
+		return queryOutput{}, newValidationError(fmt.Sprintf("csv has %d rows, exceeding the %d row limit", len(records), h.maxRows))
+	}
+
+	conn, err := h.pool.Acquire(ctx)
+	if err != nil {
+		return queryOutput{}, fmt.Errorf("postgresmcp: acquire copy connection: %w", err)
+	}
+	defer conn.Release()
+
+	ident := pgx.Identifier{ref.schema, ref.table}
+	rowCount, err := conn.Conn().CopyFrom(ctx, ident, useColumns, pgx.CopyFromRows(records))
+	if err != nil {
+		return queryOutput{}, err
+	}
+
+	return queryOutput{Command: "COPY", RowCount: rowCount}, nil
+}
+
+func (h *copyHandler) resolveTable(ctx context.Context, table string) (tableRef, error) {
+	var ref tableRef
+	rows, err := h.pool.Query(ctx, `
+		SELECT c.oid, n.nspname, c.relname
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.oid = to_regclass($1)::oid
+	`, table)
+	if err != nil {
+		return ref, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return ref, err
+		}
+		return ref, fmt.Errorf("postgresmcp: table %q not found", table)
+	}
+	if err := rows.Scan(&ref.oid, &ref.schema, &ref.table); err != nil {
+		return ref, err
+	}
+	return ref, rows.Err()
+}
+
+func (h *copyHandler) tableColumns(ctx context.Context, oid uint32) ([]string, error) {
+	rows, err := h.pool.Query(ctx, `
+		SELECT a.attname
+		FROM pg_catalog.pg_attribute a
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`, oid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols = append(cols, name)
+	}
+	return cols, rows.Err()
+}
+
+func parseCSVRows(body string, expectedCols int) ([][]any, error) {
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.FieldsPerRecord = -1
+	rawRows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("postgresmcp: parse csv: %w", err)
+	}
+
+	records := make([][]any, len(rawRows))
+	for i, row := range rawRows {
+		if expectedCols > 0 && len(row) != expectedCols {
+			return nil, newValidationError(fmt.Sprintf("csv row %d has %d fields, expected %d", i+1, len(row), expectedCols))
+		}
+		record := make([]any, len(row))
+		for j, field := range row {
+			record[j] = field
+		}
+		records[i] = record
+	}
+	return records, nil
+}
@@ -0,0 +1,80 @@
+package postgresmcp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Instrumentation receives per-call observations from tool handlers. The
+// built-in Metrics type satisfies this interface; library users that already
+// run an OpenTelemetry meter (or any other observability stack) can supply
+// their own implementation via ServerOptions.Instrumentation instead.
+type Instrumentation interface {
+	// StartCall begins tracking one invocation of tool and returns a
+	// CallRecorder used to report its outcome.
+	StartCall(tool string) CallRecorder
+}
+
+// CallRecorder reports the outcome of a single tool invocation started by
+// Instrumentation.StartCall.
+type CallRecorder interface {
+	// Rows records how many rows a successful call returned and whether the
+	// result set was truncated by a row limit.
+	Rows(count int64, truncated bool)
+	// End finalizes the call. err is nil on success.
+	End(err error)
+}
+
+const (
+	errClassValidation = "validation"
+	errClassTimeout    = "timeout"
+	errClassPg         = "pg"
+	errClassRetryable  = "retryable"
+	errClassOther      = "other"
+)
+
+// classifyError buckets err into one of the errClass* categories used by
+// Metrics to report errors by class.
+func classifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var validationErr *validationError
+	if errors.As(err, &validationErr) {
+		return errClassValidation
+	}
+	if isRetryablePgError(err) {
+		return errClassRetryable
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errClassTimeout
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return errClassPg
+	}
+	return errClassOther
+}
+
+// pgErrorCode returns the PostgreSQL SQLSTATE code for err, or "" if err is
+// not a *pgconn.PgError. Metrics uses this to break the generic "pg" error
+// class down by code (constraint violation vs. syntax error vs. ...).
+func pgErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// noopInstrumentation is used when ServerOptions.Instrumentation is nil.
+type noopInstrumentation struct{}
+
+func (noopInstrumentation) StartCall(string) CallRecorder { return noopRecorder{} }
+
+type noopRecorder struct{}
+
+func (noopRecorder) Rows(int64, bool) {}
+func (noopRecorder) End(error)        {}
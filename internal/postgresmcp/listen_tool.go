@@ -0,0 +1,135 @@
+package postgresmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// listenHandler backs postgres.listen, which subscribes to a PostgreSQL
+// LISTEN/NOTIFY channel on a dedicated connection and reports the
+// notifications received before the subscription ends. LISTEN doesn't
+// mutate data, so this tool behaves the same regardless of the server's
+// ReadOnly setting.
+type listenHandler struct {
+	pool            *pgxpool.Pool
+	requestTimeout  time.Duration
+	instrumentation Instrumentation
+}
+
+func registerListenTool(server *mcp.Server, handler *listenHandler) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "postgres.listen",
+		Description: "Subscribe to a PostgreSQL LISTEN/NOTIFY channel and report notifications received before the subscription ends.",
+	}, handler.call)
+}
+
+type listenInput struct {
+	Channel   string        `json:"channel" jsonschema:"title=Channel,description=NOTIFY channel name to subscribe to"`
+	Timeout   time.Duration `json:"timeout,omitempty" jsonschema:"title=Timeout,description=How long to wait for notifications before ending the subscription"`
+	MaxEvents int           `json:"maxEvents,omitempty" jsonschema:"title=Max events,description=Stop once this many notifications have been received,minimum=1"`
+}
+
+type notification struct {
+	Channel    string `json:"channel"`
+	Payload    string `json:"payload"`
+	PID        uint32 `json:"pid"`
+	ReceivedAt string `json:"receivedAt"`
+}
+
+type listenOutput struct {
+	Notifications []notification `json:"notifications"`
+	Elapsed       string         `json:"elapsed"`
+}
+
+var channelIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// defaultListenTimeout bounds a subscription when neither the caller nor
+// ServerOptions.RequestTimeout supplies one. Without it, a client that never
+// disconnects would hold an Acquire'd connection forever, starving the pool
+// for every other tool.
+const defaultListenTimeout = 5 * time.Minute
+
+func (h *listenHandler) call(ctx context.Context, _ *mcp.CallToolRequest, input listenInput) (*mcp.CallToolResult, listenOutput, error) {
+	channel := strings.TrimSpace(input.Channel)
+	if !channelIdentifierPattern.MatchString(channel) {
+		return nil, listenOutput{}, newValidationError("channel must match [A-Za-z_][A-Za-z0-9_]*")
+	}
+	if input.MaxEvents < 0 {
+		return nil, listenOutput{}, newValidationError("maxEvents must be positive")
+	}
+
+	timeout := input.Timeout
+	if timeout <= 0 {
+		timeout = h.requestTimeout
+	}
+	if timeout <= 0 {
+		timeout = defaultListenTimeout
+	}
+
+	rec := h.instrumentation.StartCall("postgres.listen")
+	start := time.Now()
+	notifications, err := h.subscribe(ctx, channel, timeout, input.MaxEvents)
+	rec.Rows(int64(len(notifications)), false)
+	rec.End(err)
+	if err != nil {
+		return nil, listenOutput{}, err
+	}
+
+	return nil, listenOutput{
+		Notifications: notifications,
+		Elapsed:       time.Since(start).Round(time.Millisecond).String(),
+	}, nil
+}
+
+func (h *listenHandler) subscribe(ctx context.Context, channel string, timeout time.Duration, maxEvents int) ([]notification, error) {
+	conn, err := h.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("postgresmcp: acquire listen connection: %w", err)
+	}
+	defer func() {
+		// A LISTEN left active on a returned connection would leak across
+		// whatever borrows it next from the pool, so always clear it before
+		// releasing rather than relying on the caller to clean up.
+		unlistenCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = conn.Exec(unlistenCtx, "UNLISTEN *")
+		conn.Release()
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return nil, fmt.Errorf("postgresmcp: listen on %q: %w", channel, err)
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var notifications []notification
+	for maxEvents <= 0 || len(notifications) < maxEvents {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return nil, err
+		}
+		notifications = append(notifications, notification{
+			Channel:    n.Channel,
+			Payload:    n.Payload,
+			PID:        n.PID,
+			ReceivedAt: time.Now().UTC().Format(time.RFC3339Nano),
+		})
+	}
+
+	return notifications, nil
+}
@@ -0,0 +1,386 @@
+package postgresmcp
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// histogramBucketsMs are the inclusive upper bounds, in milliseconds, of the
+// built-in elapsed-time histogram. Observations above the last bound fall
+// into an implicit +Inf bucket.
+var histogramBucketsMs = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// histogram is a lock-protected elapsed-time histogram. The counters it
+// feeds are cheap enough that a single mutex per tool is not a bottleneck
+// compared to the atomic counters used for simple sums.
+type histogram struct {
+	mu      sync.Mutex
+	buckets []int64
+	sum     float64
+	count   int64
+}
+
+func newHistogram() *histogram {
+	return &histogram{buckets: make([]int64, len(histogramBucketsMs)+1)}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sum += ms
+	h.count++
+	for i, bound := range histogramBucketsMs {
+		if ms <= bound {
+			h.buckets[i]++
+			return
+		}
+	}
+	h.buckets[len(h.buckets)-1]++
+}
+
+// snapshot returns a copy of the per-bucket counts alongside the running
+// sum/count. buckets is ordered the same as histogramBucketsMs, with a
+// trailing +Inf bucket; each entry counts only the observations that fell
+// into that specific bucket (not yet cumulative).
+func (h *histogram) snapshot() (buckets []int64, sum float64, count int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buckets = append([]int64(nil), h.buckets...)
+	return buckets, h.sum, h.count
+}
+
+// toolMetrics holds the counters tracked for a single MCP tool name.
+type toolMetrics struct {
+	calls         int64
+	inFlight      int64
+	errValidation int64
+	errTimeout    int64
+	errPg         int64
+	errRetryable  int64
+	errOther      int64
+	rows          int64
+	rowsTruncated int64
+	elapsed       *histogram
+
+	// codesMu guards codes, a map keyed by PostgreSQL SQLSTATE so operators
+	// can tell a constraint violation from a syntax error instead of seeing
+	// them both collapse into the generic "pg" error class.
+	codesMu sync.Mutex
+	codes   map[string]int64
+}
+
+func newToolMetrics() *toolMetrics {
+	return &toolMetrics{elapsed: newHistogram(), codes: make(map[string]int64)}
+}
+
+func (tm *toolMetrics) bumpCode(code string) {
+	if code == "" {
+		return
+	}
+	tm.codesMu.Lock()
+	tm.codes[code]++
+	tm.codesMu.Unlock()
+}
+
+// codeSnapshot returns a point-in-time copy of the per-SQLSTATE counters.
+func (tm *toolMetrics) codeSnapshot() map[string]int64 {
+	tm.codesMu.Lock()
+	defer tm.codesMu.Unlock()
+	out := make(map[string]int64, len(tm.codes))
+	for code, count := range tm.codes {
+		out[code] = count
+	}
+	return out
+}
+
+func (tm *toolMetrics) bumpError(class string) {
+	switch class {
+	case errClassValidation:
+		atomic.AddInt64(&tm.errValidation, 1)
+	case errClassTimeout:
+		atomic.AddInt64(&tm.errTimeout, 1)
+	case errClassPg:
+		atomic.AddInt64(&tm.errPg, 1)
+	case errClassRetryable:
+		atomic.AddInt64(&tm.errRetryable, 1)
+	case errClassOther:
+		atomic.AddInt64(&tm.errOther, 1)
+	}
+}
+
+// histogramBucket is one cumulative Prometheus-style bucket: Count observes
+// how many calls completed in at most Le milliseconds ("+Inf" for the last).
+type histogramBucket struct {
+	Le    string `json:"le"`
+	Count int64  `json:"count"`
+}
+
+// codeCount is the error count for one PostgreSQL SQLSTATE code.
+type codeCount struct {
+	Code  string `json:"code"`
+	Count int64  `json:"count"`
+}
+
+// sortedCodeCounts renders a code->count map as a deterministically ordered
+// slice, so repeated snapshots (and the Prometheus output built from them)
+// don't reorder on every call.
+func sortedCodeCounts(codes map[string]int64) []codeCount {
+	names := make([]string, 0, len(codes))
+	for code := range codes {
+		names = append(names, code)
+	}
+	sort.Strings(names)
+
+	out := make([]codeCount, len(names))
+	for i, code := range names {
+		out[i] = codeCount{Code: code, Count: codes[code]}
+	}
+	return out
+}
+
+// toolSnapshot is a point-in-time, JSON and Prometheus friendly view of a
+// toolMetrics.
+type toolSnapshot struct {
+	Tool           string            `json:"tool"`
+	Calls          int64             `json:"calls"`
+	InFlight       int64             `json:"inFlight"`
+	ErrValidation  int64             `json:"errorsValidation"`
+	ErrTimeout     int64             `json:"errorsTimeout"`
+	ErrPg          int64             `json:"errorsPg"`
+	ErrRetryable   int64             `json:"errorsRetryable"`
+	ErrOther       int64             `json:"errorsOther"`
+	ErrByCode      []codeCount       `json:"errorsByCode,omitempty"`
+	Rows           int64             `json:"rows"`
+	RowsTruncated  int64             `json:"rowsTruncated"`
+	ElapsedCount   int64             `json:"elapsedCount"`
+	ElapsedSumMs   float64           `json:"elapsedSumMs"`
+	ElapsedBuckets []histogramBucket `json:"elapsedBuckets"`
+}
+
+// cumulativeBuckets converts raw (non-cumulative) per-bucket counts into
+// Prometheus-style cumulative buckets ordered by histogramBucketsMs, ending
+// in a "+Inf" bucket equal to the total count.
+func cumulativeBuckets(raw []int64) []histogramBucket {
+	out := make([]histogramBucket, len(raw))
+	var running int64
+	for i, c := range raw {
+		running += c
+		le := "+Inf"
+		if i < len(histogramBucketsMs) {
+			le = strconv.FormatFloat(histogramBucketsMs[i], 'g', -1, 64)
+		}
+		out[i] = histogramBucket{Le: le, Count: running}
+	}
+	return out
+}
+
+// poolSnapshot is a point-in-time view of pgxpool.Stat.
+type poolSnapshot struct {
+	AcquiredConns     int32   `json:"acquiredConns"`
+	IdleConns         int32   `json:"idleConns"`
+	TotalConns        int32   `json:"totalConns"`
+	MaxConns          int32   `json:"maxConns"`
+	AcquireCount      int64   `json:"acquireCount"`
+	AcquireDurationMs float64 `json:"acquireDurationMs"`
+}
+
+// Metrics is the built-in Instrumentation implementation. It tracks per-tool
+// call counters with atomic counters; locks guard the tool map, each tool's
+// elapsed-time histogram, and each tool's per-SQLSTATE error counts. It can
+// also report pool stats snapshotted
+// from the pgxpool.Pool it was constructed with. Publish it under expvar
+// with PublishExpvar, and/or mount Handler for a Prometheus text endpoint.
+type Metrics struct {
+	pool *pgxpool.Pool
+
+	mu    sync.Mutex
+	tools map[string]*toolMetrics
+}
+
+// NewMetrics returns a Metrics instrumentation that reports pool stats from
+// pool alongside per-tool counters. pool may be nil to omit pool stats.
+func NewMetrics(pool *pgxpool.Pool) *Metrics {
+	return &Metrics{pool: pool, tools: make(map[string]*toolMetrics)}
+}
+
+func (m *Metrics) toolFor(name string) *toolMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tm, ok := m.tools[name]
+	if !ok {
+		tm = newToolMetrics()
+		m.tools[name] = tm
+	}
+	return tm
+}
+
+// StartCall implements Instrumentation.
+func (m *Metrics) StartCall(tool string) CallRecorder {
+	tm := m.toolFor(tool)
+	atomic.AddInt64(&tm.calls, 1)
+	atomic.AddInt64(&tm.inFlight, 1)
+	return &metricsRecorder{tm: tm, start: time.Now()}
+}
+
+type metricsRecorder struct {
+	tm    *toolMetrics
+	start time.Time
+}
+
+func (r *metricsRecorder) Rows(count int64, truncated bool) {
+	atomic.AddInt64(&r.tm.rows, count)
+	if truncated {
+		atomic.AddInt64(&r.tm.rowsTruncated, 1)
+	}
+}
+
+func (r *metricsRecorder) End(err error) {
+	atomic.AddInt64(&r.tm.inFlight, -1)
+	r.tm.elapsed.observe(time.Since(r.start))
+	if class := classifyError(err); class != "" {
+		r.tm.bumpError(class)
+	}
+	if code := pgErrorCode(err); code != "" {
+		r.tm.bumpCode(code)
+	}
+}
+
+func (m *Metrics) snapshot() []toolSnapshot {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.tools))
+	for name := range m.tools {
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+	sort.Strings(names)
+
+	out := make([]toolSnapshot, 0, len(names))
+	for _, name := range names {
+		tm := m.toolFor(name)
+		buckets, sum, count := tm.elapsed.snapshot()
+		out = append(out, toolSnapshot{
+			Tool:           name,
+			Calls:          atomic.LoadInt64(&tm.calls),
+			InFlight:       atomic.LoadInt64(&tm.inFlight),
+			ErrValidation:  atomic.LoadInt64(&tm.errValidation),
+			ErrTimeout:     atomic.LoadInt64(&tm.errTimeout),
+			ErrPg:          atomic.LoadInt64(&tm.errPg),
+			ErrRetryable:   atomic.LoadInt64(&tm.errRetryable),
+			ErrOther:       atomic.LoadInt64(&tm.errOther),
+			ErrByCode:      sortedCodeCounts(tm.codeSnapshot()),
+			Rows:           atomic.LoadInt64(&tm.rows),
+			RowsTruncated:  atomic.LoadInt64(&tm.rowsTruncated),
+			ElapsedCount:   count,
+			ElapsedSumMs:   sum,
+			ElapsedBuckets: cumulativeBuckets(buckets),
+		})
+	}
+	return out
+}
+
+func (m *Metrics) poolStats() *poolSnapshot {
+	if m.pool == nil {
+		return nil
+	}
+	stat := m.pool.Stat()
+	return &poolSnapshot{
+		AcquiredConns:     stat.AcquiredConns(),
+		IdleConns:         stat.IdleConns(),
+		TotalConns:        stat.TotalConns(),
+		MaxConns:          stat.MaxConns(),
+		AcquireCount:      stat.AcquireCount(),
+		AcquireDurationMs: float64(stat.AcquireDuration()) / float64(time.Millisecond),
+	}
+}
+
+// PublishExpvar publishes m under name (conventionally "postgres_mcp") in
+// the process-wide expvar registry. Like expvar.Publish, it panics if name
+// is already published, so call it at most once per process.
+func (m *Metrics) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return struct {
+			Tools []toolSnapshot `json:"tools"`
+			Pool  *poolSnapshot  `json:"pool,omitempty"`
+		}{Tools: m.snapshot(), Pool: m.poolStats()}
+	}))
+}
+
+// Handler renders the same counters in Prometheus text exposition format.
+// Mount it on the same http.Server used for MCP (e.g. at /metrics) so a
+// single listener suffices.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		defer bw.Flush()
+
+		tools := m.snapshot()
+
+		writeMetricHeader(bw, "postgres_mcp_calls_total", "counter", "Total tool calls")
+		for _, t := range tools {
+			fmt.Fprintf(bw, "postgres_mcp_calls_total{tool=%q} %d\n", t.Tool, t.Calls)
+		}
+		writeMetricHeader(bw, "postgres_mcp_calls_in_flight", "gauge", "In-flight tool calls")
+		for _, t := range tools {
+			fmt.Fprintf(bw, "postgres_mcp_calls_in_flight{tool=%q} %d\n", t.Tool, t.InFlight)
+		}
+		writeMetricHeader(bw, "postgres_mcp_errors_total", "counter", "Tool errors by class")
+		for _, t := range tools {
+			fmt.Fprintf(bw, "postgres_mcp_errors_total{tool=%q,class=\"validation\"} %d\n", t.Tool, t.ErrValidation)
+			fmt.Fprintf(bw, "postgres_mcp_errors_total{tool=%q,class=\"timeout\"} %d\n", t.Tool, t.ErrTimeout)
+			fmt.Fprintf(bw, "postgres_mcp_errors_total{tool=%q,class=\"pg\"} %d\n", t.Tool, t.ErrPg)
+			fmt.Fprintf(bw, "postgres_mcp_errors_total{tool=%q,class=\"retryable\"} %d\n", t.Tool, t.ErrRetryable)
+			fmt.Fprintf(bw, "postgres_mcp_errors_total{tool=%q,class=\"other\"} %d\n", t.Tool, t.ErrOther)
+		}
+		writeMetricHeader(bw, "postgres_mcp_errors_by_code_total", "counter", "PostgreSQL errors by SQLSTATE code")
+		for _, t := range tools {
+			for _, c := range t.ErrByCode {
+				fmt.Fprintf(bw, "postgres_mcp_errors_by_code_total{tool=%q,code=%q} %d\n", t.Tool, c.Code, c.Count)
+			}
+		}
+		writeMetricHeader(bw, "postgres_mcp_rows_total", "counter", "Rows returned")
+		for _, t := range tools {
+			fmt.Fprintf(bw, "postgres_mcp_rows_total{tool=%q} %d\n", t.Tool, t.Rows)
+		}
+		writeMetricHeader(bw, "postgres_mcp_rows_truncated_total", "counter", "Calls whose result set was truncated by a row limit")
+		for _, t := range tools {
+			fmt.Fprintf(bw, "postgres_mcp_rows_truncated_total{tool=%q} %d\n", t.Tool, t.RowsTruncated)
+		}
+		writeMetricHeader(bw, "postgres_mcp_call_duration_milliseconds", "histogram", "Tool call latency")
+		for _, t := range tools {
+			for _, b := range t.ElapsedBuckets {
+				fmt.Fprintf(bw, "postgres_mcp_call_duration_milliseconds_bucket{tool=%q,le=%q} %d\n", t.Tool, b.Le, b.Count)
+			}
+			fmt.Fprintf(bw, "postgres_mcp_call_duration_milliseconds_sum{tool=%q} %g\n", t.Tool, t.ElapsedSumMs)
+			fmt.Fprintf(bw, "postgres_mcp_call_duration_milliseconds_count{tool=%q} %d\n", t.Tool, t.ElapsedCount)
+		}
+
+		if pool := m.poolStats(); pool != nil {
+			writeMetricHeader(bw, "postgres_mcp_pool_acquired_conns", "gauge", "Acquired pool connections")
+			fmt.Fprintf(bw, "postgres_mcp_pool_acquired_conns %d\n", pool.AcquiredConns)
+			writeMetricHeader(bw, "postgres_mcp_pool_idle_conns", "gauge", "Idle pool connections")
+			fmt.Fprintf(bw, "postgres_mcp_pool_idle_conns %d\n", pool.IdleConns)
+			writeMetricHeader(bw, "postgres_mcp_pool_total_conns", "gauge", "Total pool connections")
+			fmt.Fprintf(bw, "postgres_mcp_pool_total_conns %d\n", pool.TotalConns)
+			writeMetricHeader(bw, "postgres_mcp_pool_max_conns", "gauge", "Configured maximum pool connections")
+			fmt.Fprintf(bw, "postgres_mcp_pool_max_conns %d\n", pool.MaxConns)
+			writeMetricHeader(bw, "postgres_mcp_pool_acquire_duration_milliseconds_total", "counter", "Cumulative time spent acquiring pool connections")
+			fmt.Fprintf(bw, "postgres_mcp_pool_acquire_duration_milliseconds_total %g\n", pool.AcquireDurationMs)
+		}
+	})
+}
+
+func writeMetricHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
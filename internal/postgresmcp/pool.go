@@ -0,0 +1,87 @@
+package postgresmcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolOptions configures the pgxpool.Pool constructed by NewPool. Zero values
+// leave the corresponding pgx default in place.
+type PoolOptions struct {
+	// MaxConns caps the number of open connections in the pool.
+	MaxConns int32
+	// MinConns is the minimum number of idle connections the pool keeps ready.
+	MinConns int32
+	// MaxConnLifetime bounds how long a connection may be reused before the
+	// pool closes and replaces it.
+	MaxConnLifetime time.Duration
+	// MaxConnIdleTime bounds how long a connection may sit idle before the
+	// pool closes it.
+	MaxConnIdleTime time.Duration
+	// HealthCheckPeriod controls how often the pool checks idle connections.
+	HealthCheckPeriod time.Duration
+	// StatementCacheCapacity bounds the number of prepared statements cached
+	// per connection. Zero leaves the pgx default in place.
+	StatementCacheCapacity int
+	// DescriptionCacheCapacity bounds the number of statement descriptions
+	// cached per connection. Zero leaves the pgx default in place.
+	DescriptionCacheCapacity int
+}
+
+// NewPool parses dsn and builds a pgxpool.Pool with opts applied on top of
+// the pgx defaults, mirroring the configuration knobs production Postgres
+// clients expose.
+func NewPool(ctx context.Context, dsn string, opts PoolOptions) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("postgresmcp: parse pool config: %w", err)
+	}
+	if cfg.ConnConfig.RuntimeParams == nil {
+		cfg.ConnConfig.RuntimeParams = make(map[string]string)
+	}
+	if _, exists := cfg.ConnConfig.RuntimeParams["application_name"]; !exists {
+		cfg.ConnConfig.RuntimeParams["application_name"] = "postgres-mcp-go"
+	}
+	if err := opts.Apply(cfg); err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("postgresmcp: creating pool: %w", err)
+	}
+	return pool, nil
+}
+
+// Apply overlays the configured knobs onto cfg, validating that MinConns
+// does not exceed MaxConns once both are resolved.
+func (o PoolOptions) Apply(cfg *pgxpool.Config) error {
+	if o.MaxConns > 0 {
+		cfg.MaxConns = o.MaxConns
+	}
+	if o.MinConns > 0 {
+		cfg.MinConns = o.MinConns
+	}
+	if cfg.MaxConns > 0 && cfg.MinConns > cfg.MaxConns {
+		return fmt.Errorf("postgresmcp: pool min-conns (%d) exceeds max-conns (%d)", cfg.MinConns, cfg.MaxConns)
+	}
+	if o.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = o.MaxConnLifetime
+	}
+	if o.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = o.MaxConnIdleTime
+	}
+	if o.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = o.HealthCheckPeriod
+	}
+	if o.StatementCacheCapacity > 0 {
+		cfg.ConnConfig.StatementCacheCapacity = o.StatementCacheCapacity
+	}
+	if o.DescriptionCacheCapacity > 0 {
+		cfg.ConnConfig.DescriptionCacheCapacity = o.DescriptionCacheCapacity
+	}
+	return nil
+}
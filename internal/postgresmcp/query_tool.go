@@ -5,38 +5,69 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"strings"
 	"time"
 	"unicode"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+const (
+	pgCodeSerializationFailure = "40001"
+	pgCodeDeadlockDetected     = "40P01"
+
+	defaultMaxRetries     = 2
+	defaultRetryBaseDelay = 50 * time.Millisecond
+	maxRetryDelay         = time.Second
+)
+
+// querier is the subset of *pgxpool.Pool used by queryHandler, extracted so
+// tests can substitute a fake implementation when exercising retry behavior.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
 type queryHandler struct {
-	pool           *pgxpool.Pool
-	readOnly       bool
-	maxRows        int
-	requestTimeout time.Duration
+	pool            querier
+	readOnly        bool
+	maxRows         int
+	requestTimeout  time.Duration
+	maxRetries      int
+	retryBaseDelay  time.Duration
+	instrumentation Instrumentation
 }
 
+// validationError marks a failure that is the caller's fault (bad input,
+// a disallowed statement shape) rather than a database error, so metrics
+// can bucket it separately.
+type validationError struct{ msg string }
+
+func (e *validationError) Error() string { return e.msg }
+
+func newValidationError(msg string) error { return &validationError{msg: msg} }
+
 type queryInput struct {
-	SQL     string        `json:"sql" jsonschema:"title=SQL statement,description=Statement to execute against PostgreSQL"`
-	Args    []any         `json:"args,omitempty" jsonschema:"title=Parameters,description=Positional parameters that map to $1, $2, ..."`
-	MaxRows int           `json:"maxRows,omitempty" jsonschema:"title=Row limit,description=Override the default row limit for this call,minimum=1"`
-	Meta    mcp.Meta      `json:"_meta,omitempty"`
+	SQL     string   `json:"sql" jsonschema:"title=SQL statement,description=Statement to execute against PostgreSQL"`
+	Args    []any    `json:"args,omitempty" jsonschema:"title=Parameters,description=Positional parameters that map to $1, $2, ..."`
+	MaxRows int      `json:"maxRows,omitempty" jsonschema:"title=Row limit,description=Override the default row limit for this call,minimum=1"`
+	Meta    mcp.Meta `json:"_meta,omitempty"`
 }
 
 type queryOutput struct {
-	Command   string              `json:"command"`
-	RowCount  int64               `json:"rowCount"`
-	Columns   []string            `json:"columns,omitempty"`
-	Rows      []map[string]any    `json:"rows,omitempty"`
-	Truncated bool                `json:"truncated,omitempty"`
-	Elapsed   string              `json:"elapsed"`
-	Meta      mcp.Meta            `json:"_meta,omitempty"`
+	Command   string           `json:"command"`
+	RowCount  int64            `json:"rowCount"`
+	Columns   []string         `json:"columns,omitempty"`
+	Rows      []map[string]any `json:"rows,omitempty"`
+	// Data carries a raw payload (CSV or line-delimited JSON) for tools that
+	// return a bulk blob instead of structured rows, such as postgres.copy_to.
+	Data      string   `json:"data,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+	Elapsed   string   `json:"elapsed"`
+	Meta      mcp.Meta `json:"_meta,omitempty"`
 }
 
 func registerQueryTool(server *mcp.Server, handler *queryHandler) {
@@ -46,21 +77,29 @@ func registerQueryTool(server *mcp.Server, handler *queryHandler) {
 	}, handler.call)
 }
 
-func (h *queryHandler) call(ctx context.Context, _ *mcp.CallToolRequest, input queryInput) (*mcp.CallToolResult, queryOutput, error) {
+func (h *queryHandler) call(ctx context.Context, req *mcp.CallToolRequest, input queryInput) (*mcp.CallToolResult, queryOutput, error) {
+	rec := h.instrumentation.StartCall("postgres.query")
+	result, out, err := h.doCall(ctx, req, input)
+	rec.Rows(out.RowCount, out.Truncated)
+	rec.End(err)
+	return result, out, err
+}
+
+func (h *queryHandler) doCall(ctx context.Context, _ *mcp.CallToolRequest, input queryInput) (*mcp.CallToolResult, queryOutput, error) {
 	sqlText := strings.TrimSpace(input.SQL)
 	if sqlText == "" {
-		return nil, queryOutput{}, errors.New("sql must not be empty")
+		return nil, queryOutput{}, newValidationError("sql must not be empty")
 	}
 	if !isSingleStatement(sqlText) {
-		return nil, queryOutput{}, errors.New("only a single SQL statement is supported per call")
+		return nil, queryOutput{}, newValidationError("only a single SQL statement is supported per call")
 	}
 	if h.readOnly && !isReadOnlyStatement(sqlText) {
-		return nil, queryOutput{}, errors.New("mutating statements are disabled in read-only mode")
+		return nil, queryOutput{}, newValidationError("mutating statements are disabled in read-only mode")
 	}
 
 	limit := h.maxRows
 	if input.MaxRows < 0 {
-		return nil, queryOutput{}, errors.New("maxRows must be positive")
+		return nil, queryOutput{}, newValidationError("maxRows must be positive")
 	}
 	if input.MaxRows > 0 && (limit == 0 || input.MaxRows < limit) {
 		limit = input.MaxRows
@@ -76,10 +115,49 @@ func (h *queryHandler) call(ctx context.Context, _ *mcp.CallToolRequest, input q
 		defer cancel()
 	}
 
+	maxRetries := h.maxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	baseDelay := h.retryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+
 	start := time.Now()
-	rows, err := h.pool.Query(ctx, sqlText, params...)
+	attempts := 0
+	for attempt := 0; ; attempt++ {
+		attempts++
+		out, err := h.runQuery(ctx, sqlText, params, limit)
+		if err == nil {
+			out.Elapsed = time.Since(start).Round(time.Millisecond).String()
+			out.Meta = mcp.Meta{"attempts": attempts}
+			return nil, out, nil
+		}
+		if attempt >= maxRetries || !isRetryablePgError(err) {
+			return nil, queryOutput{}, err
+		}
+		if sleepErr := sleepWithContext(ctx, retryDelay(baseDelay, attempt)); sleepErr != nil {
+			return nil, queryOutput{}, sleepErr
+		}
+	}
+}
+
+// runQuery executes sqlText once and collects its result set. A single
+// attempt never streams partial results to the caller, so it is always safe
+// to discard and retry on a transient error.
+func (h *queryHandler) runQuery(ctx context.Context, sqlText string, params []any, limit int) (queryOutput, error) {
+	return execQuery(ctx, h.pool, sqlText, params, limit)
+}
+
+// execQuery runs sqlText against q and collects its result set into a
+// queryOutput, honoring limit the same way queryHandler does. It is shared
+// by queryHandler and the read-only catalog tools so every tool reports
+// results in the same shape.
+func execQuery(ctx context.Context, q querier, sqlText string, params []any, limit int) (queryOutput, error) {
+	rows, err := q.Query(ctx, sqlText, params...)
 	if err != nil {
-		return nil, queryOutput{}, err
+		return queryOutput{}, err
 	}
 	defer rows.Close()
 
@@ -102,7 +180,7 @@ func (h *queryHandler) call(ctx context.Context, _ *mcp.CallToolRequest, input q
 		}
 		values, err := rows.Values()
 		if err != nil {
-			return nil, queryOutput{}, err
+			return queryOutput{}, err
 		}
 		record := make(map[string]any, len(values))
 		for i, col := range columns {
@@ -116,7 +194,7 @@ func (h *queryHandler) call(ctx context.Context, _ *mcp.CallToolRequest, input q
 		count++
 	}
 	if err := rows.Err(); err != nil {
-		return nil, queryOutput{}, err
+		return queryOutput{}, err
 	}
 
 	tag := rows.CommandTag()
@@ -125,16 +203,57 @@ func (h *queryHandler) call(ctx context.Context, _ *mcp.CallToolRequest, input q
 		rowCount = int64(tag.RowsAffected())
 	}
 
-	out := queryOutput{
+	return queryOutput{
 		Command:   commandString(tag),
 		RowCount:  rowCount,
 		Columns:   columns,
 		Rows:      dataRows,
 		Truncated: trunc,
-		Elapsed:   time.Since(start).Round(time.Millisecond).String(),
+	}, nil
+}
+
+// isRetryablePgError reports whether err is a PostgreSQL error that is safe
+// to retry transparently: serialization failures and deadlocks are expected
+// under contention and usually succeed on a subsequent attempt.
+func isRetryablePgError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
 	}
+	switch pgErr.Code {
+	case pgCodeSerializationFailure, pgCodeDeadlockDetected:
+		return true
+	default:
+		return false
+	}
+}
 
-	return nil, out, nil
+// retryDelay computes an exponential backoff with a small jitter, capped at
+// maxRetryDelay so contention storms don't stall a caller indefinitely.
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	d := base * time.Duration(1<<uint(attempt))
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(d/4) + 1))
+	d += jitter
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// sleepWithContext waits for d, returning early with ctx.Err() if ctx is
+// canceled or its deadline elapses first.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func applyTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
@@ -216,6 +335,14 @@ func isReadOnlyStatement(sql string) bool {
 	return ok
 }
 
+// trimTrailingSemicolon removes the single trailing semicolon
+// isSingleStatement tolerates, so callers that embed a statement inside
+// another SQL construct (e.g. copy_to's COPY (...) wrapper) don't produce a
+// syntax error from a semicolon appearing mid-statement.
+func trimTrailingSemicolon(sql string) string {
+	return strings.TrimSuffix(strings.TrimSpace(sql), ";")
+}
+
 func isSingleStatement(sql string) bool {
 	t := strings.TrimSpace(sql)
 	if t == "" {
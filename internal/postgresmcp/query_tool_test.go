@@ -0,0 +1,126 @@
+package postgresmcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeRows is a minimal pgx.Rows yielding one row of canned values, just
+// enough to exercise execQuery's collection logic.
+type fakeRows struct {
+	columns []string
+	values  [][]any
+	idx     int
+}
+
+func (r *fakeRows) Close()       {}
+func (r *fakeRows) Err() error   { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag("SELECT " + string(rune('0'+len(r.values))))
+}
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(r.columns))
+	for i, name := range r.columns {
+		fields[i] = pgconn.FieldDescription{Name: name}
+	}
+	return fields
+}
+
+func (r *fakeRows) Next() bool {
+	if r.idx >= len(r.values) {
+		return false
+	}
+	r.idx++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error { return nil }
+func (r *fakeRows) Values() ([]any, error) { return r.values[r.idx-1], nil }
+func (r *fakeRows) RawValues() [][]byte    { return nil }
+func (r *fakeRows) Conn() *pgx.Conn        { return nil }
+
+// fakeQuerier simulates a serialization failure followed by a deadlock,
+// followed by success, to exercise queryHandler's retry loop.
+type fakeQuerier struct {
+	calls int
+}
+
+func (q *fakeQuerier) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+	q.calls++
+	switch q.calls {
+	case 1:
+		return nil, &pgconn.PgError{Code: pgCodeSerializationFailure, Message: "could not serialize access"}
+	case 2:
+		return nil, &pgconn.PgError{Code: pgCodeDeadlockDetected, Message: "deadlock detected"}
+	default:
+		return &fakeRows{columns: []string{"n"}, values: [][]any{{int64(1)}}}, nil
+	}
+}
+
+func TestQueryHandlerRetriesTransientErrors(t *testing.T) {
+	q := &fakeQuerier{}
+	h := &queryHandler{
+		pool:            q,
+		maxRows:         10,
+		maxRetries:      defaultMaxRetries,
+		retryBaseDelay:  time.Millisecond,
+		instrumentation: noopInstrumentation{},
+	}
+
+	_, out, err := h.call(context.Background(), nil, queryInput{SQL: "SELECT 1"})
+	if err != nil {
+		t.Fatalf("call() returned error after expected retries: %v", err)
+	}
+	if q.calls != 3 {
+		t.Fatalf("expected 3 attempts (2 transient failures + 1 success), got %d", q.calls)
+	}
+	if attempts, _ := out.Meta["attempts"].(int); attempts != 3 {
+		t.Fatalf("expected _meta.attempts=3, got %v", out.Meta["attempts"])
+	}
+	if out.RowCount != 1 {
+		t.Fatalf("expected 1 row, got %d", out.RowCount)
+	}
+}
+
+func TestQueryHandlerStopsAfterMaxRetries(t *testing.T) {
+	q := &fakeQuerier{}
+	h := &queryHandler{
+		pool:            q,
+		maxRows:         10,
+		maxRetries:      1,
+		retryBaseDelay:  time.Millisecond,
+		instrumentation: noopInstrumentation{},
+	}
+
+	_, _, err := h.call(context.Background(), nil, queryInput{SQL: "SELECT 1"})
+	if err == nil {
+		t.Fatal("expected call() to give up and return an error")
+	}
+	if q.calls != 2 {
+		t.Fatalf("expected exactly 2 attempts (1 retry), got %d", q.calls)
+	}
+}
+
+func TestQueryHandlerRejectsEmptySQLWithoutQuerying(t *testing.T) {
+	q := &fakeQuerier{}
+	h := &queryHandler{
+		pool:            q,
+		maxRows:         10,
+		maxRetries:      defaultMaxRetries,
+		retryBaseDelay:  time.Millisecond,
+		instrumentation: noopInstrumentation{},
+	}
+
+	_, _, err := h.call(context.Background(), nil, queryInput{SQL: ""})
+	if err == nil {
+		t.Fatal("expected a validation error for an empty statement")
+	}
+	if q.calls != 0 {
+		t.Fatalf("expected the query to never reach the querier, got %d calls", q.calls)
+	}
+}
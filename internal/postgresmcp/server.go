@@ -31,9 +31,22 @@ type ServerOptions struct {
 	// tool invocation. Zero disables the additional timeout.
 	RequestTimeout time.Duration
 
+	// MaxRetries caps the number of automatic retries for a query that fails
+	// with a transient PostgreSQL error (serialization failure or deadlock).
+	// Zero falls back to the internal default.
+	MaxRetries int
+	// RetryBaseDelay is the base of the exponential backoff applied between
+	// retries. Zero falls back to the internal default.
+	RetryBaseDelay time.Duration
+
 	// Logger is used to emit diagnostic information. When nil, a default logger
 	// writing to stdout is used.
 	Logger *log.Logger
+
+	// Instrumentation receives per-call metrics (calls, errors, rows,
+	// elapsed time). When nil, calls are not instrumented. Set this to the
+	// built-in Metrics, or to an adapter over your own OpenTelemetry meter.
+	Instrumentation Instrumentation
 }
 
 // NewServer wires up an MCP server that exposes PostgreSQL via the go-sdk.
@@ -47,6 +60,15 @@ func NewServer(opts ServerOptions) (*mcp.Server, error) {
 		maxRows = defaultMaxRows
 	}
 
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+	retryBaseDelay := opts.RetryBaseDelay
+	if retryBaseDelay <= 0 {
+		retryBaseDelay = defaultRetryBaseDelay
+	}
+
 	logger := opts.Logger
 	if logger == nil {
 		logger = log.New(os.Stdout, "postgres-mcp ", log.LstdFlags|log.Lmicroseconds)
@@ -55,6 +77,9 @@ func NewServer(opts ServerOptions) (*mcp.Server, error) {
 	instructions := []string{
 		"Use the `postgres.query` tool to run SQL against PostgreSQL.",
 		"Provide JSON arguments {\"sql\": string, \"args\": array, \"maxRows\": number}.",
+		"Use postgres.list_schemas, postgres.list_tables, postgres.describe_table, and postgres.list_functions to discover the schema, and postgres.explain to inspect a plan, instead of querying pg_catalog directly.",
+		"Use postgres.listen to subscribe to a LISTEN/NOTIFY channel.",
+		"Use postgres.copy_to and postgres.copy_from for high-throughput bulk export/import via COPY.",
 	}
 	if opts.ReadOnly {
 		instructions = append(instructions, "This server enforces read-only queries.")
@@ -71,17 +96,53 @@ func NewServer(opts ServerOptions) (*mcp.Server, error) {
 		HasTools:     true,
 	})
 
+	instrumentation := opts.Instrumentation
+	if instrumentation == nil {
+		instrumentation = noopInstrumentation{}
+	}
+
 	h := queryHandler{
-		pool:           opts.Pool,
-		readOnly:       opts.ReadOnly,
-		maxRows:        maxRows,
-		requestTimeout: opts.RequestTimeout,
-		logger:         logger,
+		pool:            opts.Pool,
+		readOnly:        opts.ReadOnly,
+		maxRows:         maxRows,
+		requestTimeout:  opts.RequestTimeout,
+		maxRetries:      maxRetries,
+		retryBaseDelay:  retryBaseDelay,
+		instrumentation: instrumentation,
 	}
 
 	registerQueryTool(server, &h)
 
-	logger.Printf("server initialized readOnly=%t maxRows=%d timeout=%s", opts.ReadOnly, maxRows, opts.RequestTimeout)
+	catalog := catalogHandler{
+		pool:            opts.Pool,
+		readOnly:        opts.ReadOnly,
+		maxRows:         maxRows,
+		requestTimeout:  opts.RequestTimeout,
+		instrumentation: instrumentation,
+	}
+
+	registerCatalogTools(server, &catalog)
+
+	listener := listenHandler{
+		pool:            opts.Pool,
+		requestTimeout:  opts.RequestTimeout,
+		instrumentation: instrumentation,
+	}
+
+	registerListenTool(server, &listener)
+
+	copier := copyHandler{
+		pool:            opts.Pool,
+		readOnly:        opts.ReadOnly,
+		maxRows:         maxRows,
+		maxBytes:        defaultCopyMaxBytes,
+		requestTimeout:  opts.RequestTimeout,
+		instrumentation: instrumentation,
+	}
+
+	registerCopyTools(server, &copier)
+
+	logger.Printf("server initialized readOnly=%t maxRows=%d timeout=%s maxRetries=%d retryBaseDelay=%s", opts.ReadOnly, maxRows, opts.RequestTimeout, maxRetries, retryBaseDelay)
 
 	return server, nil
 }
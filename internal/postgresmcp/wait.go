@@ -0,0 +1,106 @@
+package postgresmcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WaitOptions configures WaitForPool's retry behavior.
+type WaitOptions struct {
+	// Pool configures the pool constructed once the database is reachable.
+	Pool PoolOptions
+	// BaseDelay is the starting backoff between ping attempts. Zero falls
+	// back to the internal default.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between ping attempts. Zero falls back to
+	// the internal default.
+	MaxDelay time.Duration
+	// Deadline bounds the overall time spent waiting for the database to
+	// become reachable. Zero disables the deadline and waits until ctx is
+	// canceled.
+	Deadline time.Duration
+
+	// Logger is used to report each failed attempt at info level. When nil,
+	// failed attempts are not logged.
+	Logger *log.Logger
+}
+
+const (
+	defaultWaitBaseDelay = 250 * time.Millisecond
+	defaultWaitMaxDelay  = 10 * time.Second
+)
+
+// WaitForPool repeatedly attempts to connect and ping dsn, backing off
+// exponentially between attempts, until a connection succeeds or opts.Deadline
+// elapses. It is meant for bring-up against a PostgreSQL instance that may
+// not be ready yet, such as during Docker/Kubernetes orchestration.
+func WaitForPool(ctx context.Context, dsn string, opts WaitOptions) (*pgxpool.Pool, error) {
+	baseDelay := opts.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultWaitBaseDelay
+	}
+	maxDelay := opts.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultWaitMaxDelay
+	}
+
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	for attempt := 0; ; attempt++ {
+		pool, err := tryConnect(ctx, dsn, opts.Pool)
+		if err == nil {
+			return pool, nil
+		}
+
+		if opts.Logger != nil {
+			opts.Logger.Printf("waiting for database: attempt %d failed: %v", attempt+1, err)
+		}
+
+		delay := waitBackoff(baseDelay, maxDelay, attempt)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("postgresmcp: waiting for database: %w", ctx.Err())
+		}
+	}
+}
+
+func tryConnect(ctx context.Context, dsn string, poolOpts PoolOptions) (*pgxpool.Pool, error) {
+	pool, err := NewPool(ctx, dsn, poolOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := pool.Ping(pingCtx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+func waitBackoff(base, max time.Duration, attempt int) time.Duration {
+	d := base * time.Duration(1<<uint(attempt))
+	if d <= 0 || d > max {
+		d = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(d/4) + 1))
+	d += jitter
+	if d > max {
+		d = max
+	}
+	return d
+}